@@ -15,7 +15,9 @@ package appconfig
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 	"sync"
 	"time"
@@ -44,11 +46,47 @@ const (
 	defaultRetryDelay            = time.Second * 4
 	defaultMaxRetryDelay         = time.Second * 120
 	defaultSubscribePollInterval = time.Second * 30
+
+	subscribeModeMeta    = "subscribeMode"
+	pushBindAddressMeta  = "pushBindAddress"
+	pushPathMeta         = "pushPath"
+	defaultSubscribeMode = subscribeModePoll
+	defaultPushPath      = "/"
+
+	subscribeModePoll   = "poll"
+	subscribeModePush   = "push"
+	subscribeModeHybrid = "hybrid"
+
+	resolveKeyVaultRefsMeta    = "resolveKeyVaultRefs"
+	keyVaultRefCacheTTLMeta    = "keyVaultRefCacheTTL"
+	defaultResolveKeyVaultRefs = true
+	defaultKeyVaultRefCacheTTL = time.Minute * 5
+
+	// snapshotMeta is the GetRequest/SubscribeRequest metadata key used to pin a Get to a named,
+	// point-in-time snapshot instead of the latest key/label values. Only a Get with no Keys (the
+	// getAll path) reads from the snapshot; a Get that names Keys ignores it and reads the latest
+	// values. Subscribe rejects a request that sets it.
+	snapshotMeta = "snapshot"
+
+	subscribeMaxConsecutiveFailuresMeta    = "subscribeMaxConsecutiveFailures"
+	subscribeRetryDelayMeta                = "subscribeRetryDelay"
+	subscribeMaxRetryDelayMeta             = "subscribeMaxRetryDelay"
+	subscribeOnErrorPolicyMeta             = "subscribeOnErrorPolicy"
+	defaultSubscribeMaxConsecutiveFailures = 5
+	defaultSubscribeRetryDelay             = time.Second * 2
+	defaultSubscribeMaxRetryDelay          = time.Second * 60
+	defaultSubscribeOnErrorPolicy          = subscribeOnErrorContinue
+
+	subscribeOnErrorContinue = "continue"
+	subscribeOnErrorStop     = "stop"
 )
 
 type azAppConfigClient interface {
 	GetSetting(ctx context.Context, key string, options *azappconfig.GetSettingOptions) (azappconfig.GetSettingResponse, error)
 	NewListSettingsPager(selector azappconfig.SettingSelector, options *azappconfig.ListSettingsOptions) *runtime.Pager[azappconfig.ListSettingsPage]
+	BeginCreateSnapshot(ctx context.Context, name string, snapshot azappconfig.Snapshot, options *azappconfig.BeginCreateSnapshotOptions) (*runtime.Poller[azappconfig.CreateSnapshotResponse], error)
+	BeginArchiveSnapshot(ctx context.Context, name string, options *azappconfig.BeginArchiveSnapshotOptions) (*runtime.Poller[azappconfig.ArchiveSnapshotResponse], error)
+	NewListSnapshotsPager(options *azappconfig.ListSnapshotsOptions) *runtime.Pager[azappconfig.ListSnapshotsPage]
 }
 
 // ConfigurationStore is a Azure App Configuration store.
@@ -56,8 +94,29 @@ type ConfigurationStore struct {
 	client               azAppConfigClient
 	metadata             metadata
 	subscribeStopChanMap sync.Map
-	keysToSubIDMap       map[string]string
-	subIDToKeyListMap    map[string][]string
+	// subMu guards keysToSubIDMap, subIDToKeyListMap and subIDToLabelMap: Subscribe/Unsubscribe
+	// mutate them and, once push mode is enabled, the Event Grid webhook handler reads them
+	// concurrently from its own goroutine per delivery.
+	subMu             sync.Mutex
+	keysToSubIDMap    map[string]string
+	subIDToKeyListMap map[string][]string
+	// subIDToLabelMap tracks the label (possibly "") each subscription was created with, so the
+	// Event Grid push listener can tell an update on the same key but a different label apart
+	// instead of delivering it to every subscription on that key regardless of label.
+	subIDToLabelMap map[string]string
+	// etagMap tracks the last-seen ETag for each subscribed key, keyed by "<subscribeID>|<key>",
+	// so that doSubscribe can issue conditional GetSetting calls instead of polling a sentinel key.
+	etagMap sync.Map
+	// subscribeHandlerMap keeps the UpdateHandler for each active subscription so the Event Grid
+	// push listener can invoke it directly, without a doSubscribe poll loop in scope.
+	subscribeHandlerMap sync.Map
+	pushServer          *http.Server
+
+	// cred and secretClients back Key Vault reference resolution: cred is only set when Init
+	// authenticates via azauth rather than a connection string.
+	cred          azcore.TokenCredential
+	secretClients sync.Map
+	keyVaultCache *ttlLRUCache
 
 	logger logger.Logger
 }
@@ -67,6 +126,8 @@ func NewAzureAppConfigurationStore(logger logger.Logger) configuration.Store {
 	s := &ConfigurationStore{
 		keysToSubIDMap:    make(map[string]string),
 		subIDToKeyListMap: make(map[string][]string),
+		subIDToLabelMap:   make(map[string]string),
+		keyVaultCache:     newTTLLRUCache(keyVaultCacheCapacity),
 		logger:            logger,
 	}
 
@@ -113,6 +174,7 @@ func (r *ConfigurationStore) Init(metadata configuration.Metadata) error {
 		if err != nil {
 			return err
 		}
+		r.cred = cred
 
 		r.client, err = azappconfig.NewClient(r.metadata.host, cred, &options)
 		if err != nil {
@@ -120,6 +182,12 @@ func (r *ConfigurationStore) Init(metadata configuration.Metadata) error {
 		}
 	}
 
+	if r.metadata.subscribeMode != subscribeModePoll {
+		if err := r.startPushListener(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -178,6 +246,83 @@ func parseMetadata(meta configuration.Metadata) (metadata, error) {
 		m.subscribePollInterval = time.Duration(parsedVal)
 	}
 
+	m.subscribeMode = defaultSubscribeMode
+	if val, ok := meta.Properties[subscribeModeMeta]; ok && val != "" {
+		switch val {
+		case subscribeModePoll, subscribeModePush, subscribeModeHybrid:
+			m.subscribeMode = val
+		default:
+			return m, fmt.Errorf("azure appconfig error: invalid %s field: %s", subscribeModeMeta, val)
+		}
+	}
+
+	if val, ok := meta.Properties[pushBindAddressMeta]; ok && val != "" {
+		m.pushBindAddress = val
+	}
+	if m.subscribeMode != subscribeModePoll && m.pushBindAddress == "" {
+		return m, fmt.Errorf("azure appconfig error: %s is required when %s is %q or %q", pushBindAddressMeta, subscribeModeMeta, subscribeModePush, subscribeModeHybrid)
+	}
+
+	m.pushPath = defaultPushPath
+	if val, ok := meta.Properties[pushPathMeta]; ok && val != "" {
+		m.pushPath = val
+	}
+
+	m.resolveKeyVaultRefs = defaultResolveKeyVaultRefs
+	if val, ok := meta.Properties[resolveKeyVaultRefsMeta]; ok && val != "" {
+		parsedVal, err := strconv.ParseBool(val)
+		if err != nil {
+			return m, fmt.Errorf("azure appconfig error: can't parse %s field: %s", resolveKeyVaultRefsMeta, err)
+		}
+		m.resolveKeyVaultRefs = parsedVal
+	}
+
+	m.keyVaultRefCacheTTL = defaultKeyVaultRefCacheTTL
+	if val, ok := meta.Properties[keyVaultRefCacheTTLMeta]; ok && val != "" {
+		parsedVal, err := strconv.Atoi(val)
+		if err != nil {
+			return m, fmt.Errorf("azure appconfig error: can't parse %s field: %s", keyVaultRefCacheTTLMeta, err)
+		}
+		m.keyVaultRefCacheTTL = time.Duration(parsedVal)
+	}
+
+	m.subscribeMaxConsecutiveFailures = defaultSubscribeMaxConsecutiveFailures
+	if val, ok := meta.Properties[subscribeMaxConsecutiveFailuresMeta]; ok && val != "" {
+		parsedVal, err := strconv.Atoi(val)
+		if err != nil {
+			return m, fmt.Errorf("azure appconfig error: can't parse %s field: %s", subscribeMaxConsecutiveFailuresMeta, err)
+		}
+		m.subscribeMaxConsecutiveFailures = parsedVal
+	}
+
+	m.subscribeRetryDelay = defaultSubscribeRetryDelay
+	if val, ok := meta.Properties[subscribeRetryDelayMeta]; ok && val != "" {
+		parsedVal, err := strconv.Atoi(val)
+		if err != nil {
+			return m, fmt.Errorf("azure appconfig error: can't parse %s field: %s", subscribeRetryDelayMeta, err)
+		}
+		m.subscribeRetryDelay = time.Duration(parsedVal)
+	}
+
+	m.subscribeMaxRetryDelay = defaultSubscribeMaxRetryDelay
+	if val, ok := meta.Properties[subscribeMaxRetryDelayMeta]; ok && val != "" {
+		parsedVal, err := strconv.Atoi(val)
+		if err != nil {
+			return m, fmt.Errorf("azure appconfig error: can't parse %s field: %s", subscribeMaxRetryDelayMeta, err)
+		}
+		m.subscribeMaxRetryDelay = time.Duration(parsedVal)
+	}
+
+	m.subscribeOnErrorPolicy = defaultSubscribeOnErrorPolicy
+	if val, ok := meta.Properties[subscribeOnErrorPolicyMeta]; ok && val != "" {
+		switch val {
+		case subscribeOnErrorContinue, subscribeOnErrorStop:
+			m.subscribeOnErrorPolicy = val
+		default:
+			return m, fmt.Errorf("azure appconfig error: invalid %s field: %s", subscribeOnErrorPolicyMeta, val)
+		}
+	}
+
 	return m, nil
 }
 
@@ -204,14 +349,8 @@ func (r *ConfigurationStore) Get(ctx context.Context, req *configuration.GetRequ
 				return &configuration.GetResponse{}, err
 			}
 
-			item := &configuration.Item{
-				Metadata: map[string]string{},
-			}
-			item.Value = *resp.Value
-			if resp.Label != nil {
-				item.Metadata["label"] = *resp.Label
-			}
-
+			item := settingToItem(resp.Label, resp.ContentType, resp.Value)
+			r.resolveKeyVaultRef(ctx, item)
 			items[key] = item
 		}
 	}
@@ -224,30 +363,26 @@ func (r *ConfigurationStore) Get(ctx context.Context, req *configuration.GetRequ
 func (r *ConfigurationStore) getAll(ctx context.Context, req *configuration.GetRequest) (map[string]*configuration.Item, error) {
 	items := make(map[string]*configuration.Item, 0)
 
-	labelFilter := r.getLabelFromMetadata(req.Metadata)
-	if labelFilter == nil {
-		labelFilter = to.Ptr("*")
+	selector := azappconfig.SettingSelector{Fields: azappconfig.AllSettingFields()}
+	if snapshotName := req.Metadata[snapshotMeta]; snapshotName != "" {
+		// Snapshots pin a consistent key/label view, so key and label filters don't apply.
+		selector.SnapshotName = to.Ptr(snapshotName)
+	} else {
+		labelFilter := r.getLabelFromMetadata(req.Metadata)
+		if labelFilter == nil {
+			labelFilter = to.Ptr("*")
+		}
+		selector.KeyFilter = to.Ptr("*")
+		selector.LabelFilter = labelFilter
 	}
 
-	allSettingsPgr := r.client.NewListSettingsPager(
-		azappconfig.SettingSelector{
-			KeyFilter:   to.Ptr("*"),
-			LabelFilter: labelFilter,
-			Fields:      azappconfig.AllSettingFields(),
-		},
-		nil)
+	allSettingsPgr := r.client.NewListSettingsPager(selector, nil)
 
 	for allSettingsPgr.More() {
 		if revResp, err := allSettingsPgr.NextPage(ctx); err == nil {
 			for _, setting := range revResp.Settings {
-				item := &configuration.Item{
-					Metadata: map[string]string{},
-				}
-				item.Value = *setting.Value
-				if setting.Label != nil {
-					item.Metadata["label"] = *setting.Label
-				}
-
+				item := settingToItem(setting.Label, setting.ContentType, setting.Value)
+				r.resolveKeyVaultRef(ctx, item)
 				items[*setting.Key] = item
 			}
 		} else {
@@ -257,6 +392,24 @@ func (r *ConfigurationStore) getAll(ctx context.Context, req *configuration.GetR
 	return items, nil
 }
 
+func settingToItem(label, contentType, value *string) *configuration.Item {
+	item := &configuration.Item{
+		Metadata: map[string]string{},
+	}
+	item.Value = *value
+	if label != nil {
+		item.Metadata["label"] = *label
+	}
+	if contentType != nil {
+		item.Metadata["contentType"] = *contentType
+		if *contentType == featureFlagContentType {
+			item.Metadata["isFeatureFlag"] = "true"
+		}
+	}
+
+	return item
+}
+
 func (r *ConfigurationStore) getLabelFromMetadata(metadata map[string]string) *string {
 	if s, ok := metadata["label"]; ok && s != "" {
 		return to.Ptr(s)
@@ -265,14 +418,22 @@ func (r *ConfigurationStore) getLabelFromMetadata(metadata map[string]string) *s
 	return nil
 }
 
+// labelFromMetadata returns the label a request's metadata specifies, or "" if it specifies none,
+// mirroring how getLabelFromMetadata's nil return corresponds to the store's no-label setting.
+func labelFromMetadata(metadata map[string]string) string {
+	return metadata["label"]
+}
+
 func (r *ConfigurationStore) Subscribe(ctx context.Context, req *configuration.SubscribeRequest, handler configuration.UpdateHandler) (string, error) {
+	if snapshotName := req.Metadata[snapshotMeta]; snapshotName != "" {
+		return "", fmt.Errorf("azure appconfig error: cannot subscribe against snapshot %q, snapshots are immutable", snapshotName)
+	}
+
 	subscribeID := uuid.New().String()
 	stop := make(chan struct{})
 	r.subscribeStopChanMap.Store(subscribeID, stop)
-	sentinelKey := r.getSentinelKeyFromMetadata(req.Metadata)
-	if sentinelKey == "" {
-		return "", fmt.Errorf("sentinel key is not provided in metadata")
-	}
+
+	r.subMu.Lock()
 	actualKeyToSubscribeSlice := make([]string, 0)
 	for _, key := range req.Keys {
 		_, found := r.keysToSubIDMap[key]
@@ -282,6 +443,7 @@ func (r *ConfigurationStore) Subscribe(ctx context.Context, req *configuration.S
 		}
 	}
 	if len(actualKeyToSubscribeSlice) == 0 {
+		r.subMu.Unlock()
 		return "", fmt.Errorf("all provided keys are already subscribed")
 	}
 	value, found := r.subIDToKeyListMap[subscribeID]
@@ -291,43 +453,186 @@ func (r *ConfigurationStore) Subscribe(ctx context.Context, req *configuration.S
 	} else {
 		r.subIDToKeyListMap[subscribeID] = actualKeyToSubscribeSlice
 	}
+	r.subIDToLabelMap[subscribeID] = labelFromMetadata(req.Metadata)
+	r.subMu.Unlock()
 
 	req.Keys = actualKeyToSubscribeSlice
-	go r.doSubscribe(ctx, req, handler, sentinelKey, subscribeID, stop)
+	r.subscribeHandlerMap.Store(subscribeID, handler)
+	r.recordInitialETags(ctx, req, subscribeID)
+	if r.metadata.subscribeMode != subscribeModePush {
+		go r.doSubscribe(ctx, req, handler, subscribeID, stop)
+	}
 	return subscribeID, nil
 }
 
-func (r *ConfigurationStore) doSubscribe(ctx context.Context, req *configuration.SubscribeRequest, handler configuration.UpdateHandler, sentinelKey string, id string, stop chan struct{}) {
-	for {
-		// get sentinel key changes
-		_, err := r.Get(ctx, &configuration.GetRequest{
-			Keys:     []string{sentinelKey},
-			Metadata: req.Metadata,
+// recordInitialETags seeds etagMap with each subscribed key's current ETag so the first poll
+// tick has something to compare against.
+func (r *ConfigurationStore) recordInitialETags(ctx context.Context, req *configuration.SubscribeRequest, subscribeID string) {
+	for _, key := range req.Keys {
+		resp, err := r.client.GetSetting(ctx, key, &azappconfig.GetSettingOptions{
+			Label: r.getLabelFromMetadata(req.Metadata),
 		})
 		if err != nil {
-			r.logger.Debugf("fail to get sentinel key changes or sentinel key's value is unchanged: %s", err)
-		} else {
-			items, err := r.Get(ctx, &configuration.GetRequest{
-				Keys:     req.Keys,
-				Metadata: req.Metadata,
-			})
-			if err != nil {
-				r.logger.Errorf("fail to get configuration key changes: %s", err)
-			} else {
-				r.handleSubscribedChange(ctx, req, handler, items, id)
+			r.logger.Warnf("azure appconfig: failed to fetch initial value for key %s: %s", key, err)
+			continue
+		}
+		if resp.ETag != nil {
+			r.etagMap.Store(etagMapKey(subscribeID, key), string(*resp.ETag))
+		}
+	}
+}
+
+// subscribeErrorItemKey is the synthetic Items key used to surface a poll failure through the
+// same UpdateEvent shape as a real change, since this tree's configuration.UpdateEvent carries
+// no Error field of its own to extend.
+const subscribeErrorItemKey = "__subscribe_error__"
+
+// subscribePollState is doSubscribe's backoff state, pulled out of the loop so
+// advanceSubscribePollState can be unit tested without driving real timers.
+type subscribePollState struct {
+	consecutiveFailures int
+	retryDelay          time.Duration
+}
+
+func (r *ConfigurationStore) doSubscribe(ctx context.Context, req *configuration.SubscribeRequest, handler configuration.UpdateHandler, id string, stop chan struct{}) {
+	state := subscribePollState{retryDelay: r.metadata.subscribeRetryDelay}
+
+	for {
+		changed, pollErr := r.pollChangedKeys(ctx, req, id)
+
+		var wait time.Duration
+		var notifyFailures int
+		var haltLoop bool
+		state, wait, notifyFailures, haltLoop = r.advanceSubscribePollState(state, pollErr)
+
+		// A failure on one subscribed key must not swallow changes pollChangedKeys already found
+		// for the rest, so deliver changed and notify of pollErr independently.
+		if len(changed) > 0 {
+			r.handleSubscribedChange(ctx, handler, changed, id)
+		}
+
+		if pollErr != nil {
+			r.logger.Errorf("fail to get configuration key changes: %s", pollErr)
+			if notifyFailures > 0 {
+				r.logger.Errorf("azure appconfig: subscription %s reached %d consecutive poll failures, applying %s policy", id, notifyFailures, r.metadata.subscribeOnErrorPolicy)
+				r.notifySubscribeError(ctx, handler, id, pollErr, notifyFailures)
 			}
 		}
+
+		if haltLoop {
+			return
+		}
+
 		select {
 		case <-stop:
 			return
 		case <-ctx.Done():
 			return
-		case <-time.After(r.metadata.subscribePollInterval):
+		case <-time.After(wait):
 		}
 	}
 }
 
-func (r *ConfigurationStore) handleSubscribedChange(ctx context.Context, req *configuration.SubscribeRequest, handler configuration.UpdateHandler, items *configuration.GetResponse, id string) {
+// advanceSubscribePollState decides the next backoff state, the wait before the next poll, and
+// whether the caller's handler should be notified of the failure, given the outcome of one poll.
+// notifyFailures is the consecutive-failure count at the point subscribeMaxConsecutiveFailures
+// was reached (0 means no notification is due this tick). haltLoop tells doSubscribe to stop
+// polling entirely, which only happens under subscribeOnErrorStop.
+func (r *ConfigurationStore) advanceSubscribePollState(state subscribePollState, pollErr error) (next subscribePollState, wait time.Duration, notifyFailures int, haltLoop bool) {
+	if pollErr == nil {
+		return subscribePollState{retryDelay: r.metadata.subscribeRetryDelay}, r.metadata.subscribePollInterval, 0, false
+	}
+
+	failures := state.consecutiveFailures + 1
+	wait = state.retryDelay
+
+	nextDelay := state.retryDelay * 2
+	if nextDelay > r.metadata.subscribeMaxRetryDelay {
+		nextDelay = r.metadata.subscribeMaxRetryDelay
+	}
+
+	if failures >= r.metadata.subscribeMaxConsecutiveFailures {
+		notifyFailures = failures
+		if r.metadata.subscribeOnErrorPolicy == subscribeOnErrorStop {
+			haltLoop = true
+		} else {
+			failures = 0
+		}
+	}
+
+	next = subscribePollState{consecutiveFailures: failures, retryDelay: nextDelay}
+
+	return next, wait, notifyFailures, haltLoop
+}
+
+// notifySubscribeError invokes handler with a synthetic UpdateEvent so the subscribing app can
+// observe that its subscription is degraded or has stopped, instead of failures only being
+// visible in this component's own logs.
+func (r *ConfigurationStore) notifySubscribeError(ctx context.Context, handler configuration.UpdateHandler, id string, pollErr error, consecutiveFailures int) {
+	item := &configuration.Item{
+		Value: pollErr.Error(),
+		Metadata: map[string]string{
+			"error":               "true",
+			"consecutiveFailures": strconv.Itoa(consecutiveFailures),
+		},
+	}
+	r.handleSubscribedChange(ctx, handler, map[string]*configuration.Item{subscribeErrorItemKey: item}, id)
+}
+
+// pollChangedKeys issues a conditional GetSetting per subscribed key using the last-seen ETag and
+// returns only the keys whose value actually changed, instead of polling a sentinel key and
+// re-fetching everything on any bump. A single key's failure never stops the rest of req.Keys from
+// being checked: key-not-found is treated as a deletion, and any other per-key error is collected
+// and returned alongside whatever other keys did change, instead of aborting the poll outright.
+func (r *ConfigurationStore) pollChangedKeys(ctx context.Context, req *configuration.SubscribeRequest, id string) (map[string]*configuration.Item, error) {
+	changed := make(map[string]*configuration.Item)
+	var pollErrs []error
+
+	for _, key := range req.Keys {
+		mapKey := etagMapKey(id, key)
+
+		var ifChanged *azcore.ETag
+		if v, ok := r.etagMap.Load(mapKey); ok {
+			ifChanged = to.Ptr(azcore.ETag(v.(string)))
+		}
+
+		resp, err := r.client.GetSetting(ctx, key, &azappconfig.GetSettingOptions{
+			Label:         r.getLabelFromMetadata(req.Metadata),
+			OnlyIfChanged: ifChanged,
+		})
+		if err != nil {
+			var respErr *azcore.ResponseError
+			if errors.As(err, &respErr) {
+				switch respErr.StatusCode {
+				case http.StatusNotModified:
+					continue
+				case http.StatusNotFound:
+					// The key was deleted server-side: drop its ETag so a later re-creation is
+					// seen as a fresh change, but this isn't a failure of the subscription itself.
+					r.etagMap.Delete(mapKey)
+					continue
+				}
+			}
+			pollErrs = append(pollErrs, fmt.Errorf("key %s: %w", key, err))
+			continue
+		}
+
+		if resp.ETag != nil {
+			r.etagMap.Store(mapKey, string(*resp.ETag))
+		}
+		item := settingToItem(resp.Label, resp.ContentType, resp.Value)
+		r.resolveKeyVaultRef(ctx, item)
+		changed[key] = item
+	}
+
+	return changed, errors.Join(pollErrs...)
+}
+
+func etagMapKey(subscribeID, key string) string {
+	return subscribeID + "|" + key
+}
+
+func (r *ConfigurationStore) handleSubscribedChange(ctx context.Context, handler configuration.UpdateHandler, items map[string]*configuration.Item, id string) {
 	defer func() {
 		if err := recover(); err != nil {
 			r.logger.Errorf("panic in handleSubscribedChange(）method and recovered: %s", err)
@@ -335,7 +640,7 @@ func (r *ConfigurationStore) handleSubscribedChange(ctx context.Context, req *co
 	}()
 
 	e := &configuration.UpdateEvent{
-		Items: items.Items,
+		Items: items,
 		ID:    id,
 	}
 	err := handler(ctx, e)
@@ -344,18 +649,14 @@ func (r *ConfigurationStore) handleSubscribedChange(ctx context.Context, req *co
 	}
 }
 
-func (r *ConfigurationStore) getSentinelKeyFromMetadata(metadata map[string]string) string {
-	if s, ok := metadata["sentinelKey"]; ok && s != "" {
-		return s
-	}
-	return ""
-}
-
 func (r *ConfigurationStore) Unsubscribe(ctx context.Context, req *configuration.UnsubscribeRequest) error {
 	if oldStopChan, ok := r.subscribeStopChanMap.Load(req.ID); ok {
 		// already exist subscription
 		r.subscribeStopChanMap.Delete(req.ID)
 		close(oldStopChan.(chan struct{}))
+		r.subscribeHandlerMap.Delete(req.ID)
+
+		r.subMu.Lock()
 		keys, found := r.subIDToKeyListMap[req.ID]
 		if found {
 			for _, key := range keys {
@@ -363,6 +664,12 @@ func (r *ConfigurationStore) Unsubscribe(ctx context.Context, req *configuration
 			}
 			delete(r.subIDToKeyListMap, req.ID)
 		}
+		delete(r.subIDToLabelMap, req.ID)
+		r.subMu.Unlock()
+
+		for _, key := range keys {
+			r.etagMap.Delete(etagMapKey(req.ID, key))
+		}
 	}
 	return nil
 }
@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azappconfig"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/dapr/components-contrib/configuration"
+	"github.com/dapr/kit/logger"
+)
+
+// fakeAppConfigClient implements azAppConfigClient using a scripted sequence of GetSetting
+// responses, so pollChangedKeys's ETag handling can be tested without a real App Configuration
+// instance. The snapshot/listing methods are not exercised by these tests and panic if called.
+type fakeAppConfigClient struct {
+	getSettingResponses []azappconfig.GetSettingResponse
+	getSettingErrs      []error
+	call                int
+}
+
+func (f *fakeAppConfigClient) GetSetting(_ context.Context, _ string, _ *azappconfig.GetSettingOptions) (azappconfig.GetSettingResponse, error) {
+	i := f.call
+	f.call++
+	return f.getSettingResponses[i], f.getSettingErrs[i]
+}
+
+func (f *fakeAppConfigClient) NewListSettingsPager(azappconfig.SettingSelector, *azappconfig.ListSettingsOptions) *runtime.Pager[azappconfig.ListSettingsPage] {
+	panic("not implemented")
+}
+
+func (f *fakeAppConfigClient) BeginCreateSnapshot(context.Context, string, azappconfig.Snapshot, *azappconfig.BeginCreateSnapshotOptions) (*runtime.Poller[azappconfig.CreateSnapshotResponse], error) {
+	panic("not implemented")
+}
+
+func (f *fakeAppConfigClient) BeginArchiveSnapshot(context.Context, string, *azappconfig.BeginArchiveSnapshotOptions) (*runtime.Poller[azappconfig.ArchiveSnapshotResponse], error) {
+	panic("not implemented")
+}
+
+func (f *fakeAppConfigClient) NewListSnapshotsPager(*azappconfig.ListSnapshotsOptions) *runtime.Pager[azappconfig.ListSnapshotsPage] {
+	panic("not implemented")
+}
+
+func notModifiedErr() error {
+	return &azcore.ResponseError{StatusCode: http.StatusNotModified}
+}
+
+func notFoundErr() error {
+	return &azcore.ResponseError{StatusCode: http.StatusNotFound}
+}
+
+func newTestConfigurationStore(client azAppConfigClient) *ConfigurationStore {
+	return &ConfigurationStore{
+		client: client,
+		logger: logger.NewLogger("test"),
+	}
+}
+
+func TestPollChangedKeys_200ReturnsItemAndUpdatesETag(t *testing.T) {
+	r := newTestConfigurationStore(&fakeAppConfigClient{
+		getSettingResponses: []azappconfig.GetSettingResponse{
+			{
+				Setting: azappconfig.Setting{
+					Value: to.Ptr("new-value"),
+					ETag:  to.Ptr(azcore.ETag("etag-2")),
+				},
+			},
+		},
+		getSettingErrs: []error{nil},
+	})
+
+	req := &configuration.SubscribeRequest{Keys: []string{"key1"}}
+	changed, err := r.pollChangedKeys(context.Background(), req, "sub-1")
+
+	require.NoError(t, err)
+	require.Contains(t, changed, "key1")
+	assert.Equal(t, "new-value", changed["key1"].Value)
+
+	stored, ok := r.etagMap.Load(etagMapKey("sub-1", "key1"))
+	require.True(t, ok)
+	assert.Equal(t, "etag-2", stored)
+}
+
+func TestPollChangedKeys_304SkipsKeyAndKeepsETag(t *testing.T) {
+	r := newTestConfigurationStore(&fakeAppConfigClient{
+		getSettingErrs: []error{notModifiedErr()},
+		getSettingResponses: []azappconfig.GetSettingResponse{
+			{},
+		},
+	})
+	r.etagMap.Store(etagMapKey("sub-1", "key1"), "etag-1")
+
+	req := &configuration.SubscribeRequest{Keys: []string{"key1"}}
+	changed, err := r.pollChangedKeys(context.Background(), req, "sub-1")
+
+	require.NoError(t, err)
+	assert.NotContains(t, changed, "key1")
+
+	stored, ok := r.etagMap.Load(etagMapKey("sub-1", "key1"))
+	require.True(t, ok)
+	assert.Equal(t, "etag-1", stored)
+}
+
+func TestPollChangedKeys_OtherErrorPropagates(t *testing.T) {
+	r := newTestConfigurationStore(&fakeAppConfigClient{
+		getSettingErrs:      []error{assert.AnError},
+		getSettingResponses: []azappconfig.GetSettingResponse{{}},
+	})
+
+	req := &configuration.SubscribeRequest{Keys: []string{"key1"}}
+	_, err := r.pollChangedKeys(context.Background(), req, "sub-1")
+
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestPollChangedKeys_NotFoundDropsETagWithoutError(t *testing.T) {
+	r := newTestConfigurationStore(&fakeAppConfigClient{
+		getSettingErrs:      []error{notFoundErr()},
+		getSettingResponses: []azappconfig.GetSettingResponse{{}},
+	})
+	r.etagMap.Store(etagMapKey("sub-1", "key1"), "etag-1")
+
+	req := &configuration.SubscribeRequest{Keys: []string{"key1"}}
+	changed, err := r.pollChangedKeys(context.Background(), req, "sub-1")
+
+	require.NoError(t, err)
+	assert.NotContains(t, changed, "key1")
+
+	_, ok := r.etagMap.Load(etagMapKey("sub-1", "key1"))
+	assert.False(t, ok, "a deleted key's stale ETag should be dropped")
+}
+
+func TestPollChangedKeys_OneKeyErrorDoesNotBlockOthers(t *testing.T) {
+	r := newTestConfigurationStore(&fakeAppConfigClient{
+		getSettingErrs: []error{assert.AnError, nil},
+		getSettingResponses: []azappconfig.GetSettingResponse{
+			{},
+			{
+				Setting: azappconfig.Setting{
+					Value: to.Ptr("healthy-value"),
+					ETag:  to.Ptr(azcore.ETag("etag-b")),
+				},
+			},
+		},
+	})
+
+	req := &configuration.SubscribeRequest{Keys: []string{"keyA", "keyB"}}
+	changed, err := r.pollChangedKeys(context.Background(), req, "sub-1")
+
+	require.Error(t, err, "the failing key's error should still be surfaced")
+	assert.ErrorIs(t, err, assert.AnError)
+
+	require.Contains(t, changed, "keyB", "a healthy key after a failing one must still be reported")
+	assert.Equal(t, "healthy-value", changed["keyB"].Value)
+	assert.NotContains(t, changed, "keyA")
+}
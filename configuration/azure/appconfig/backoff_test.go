@@ -0,0 +1,127 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dapr/components-contrib/configuration"
+	"github.com/dapr/kit/logger"
+)
+
+func newTestStore(onErrorPolicy string) *ConfigurationStore {
+	return &ConfigurationStore{
+		metadata: metadata{
+			subscribeRetryDelay:             time.Second,
+			subscribeMaxRetryDelay:          time.Second * 8,
+			subscribeMaxConsecutiveFailures: 3,
+			subscribePollInterval:           time.Second * 30,
+			subscribeOnErrorPolicy:          onErrorPolicy,
+		},
+	}
+}
+
+func TestAdvanceSubscribePollState_Success(t *testing.T) {
+	r := newTestStore(subscribeOnErrorContinue)
+	state := subscribePollState{consecutiveFailures: 2, retryDelay: time.Second * 4}
+
+	next, wait, notifyFailures, haltLoop := r.advanceSubscribePollState(state, nil)
+
+	assert.Equal(t, 0, next.consecutiveFailures)
+	assert.Equal(t, r.metadata.subscribeRetryDelay, next.retryDelay)
+	assert.Equal(t, r.metadata.subscribePollInterval, wait)
+	assert.Equal(t, 0, notifyFailures)
+	assert.False(t, haltLoop)
+}
+
+func TestAdvanceSubscribePollState_DoublesDelayUpToMax(t *testing.T) {
+	r := newTestStore(subscribeOnErrorContinue)
+	state := subscribePollState{retryDelay: time.Second}
+	pollErr := errors.New("boom")
+
+	state, wait, _, _ := r.advanceSubscribePollState(state, pollErr)
+	assert.Equal(t, time.Second, wait)
+	assert.Equal(t, time.Second*2, state.retryDelay)
+
+	state, wait, _, _ = r.advanceSubscribePollState(state, pollErr)
+	assert.Equal(t, time.Second*2, wait)
+	assert.Equal(t, time.Second*4, state.retryDelay)
+
+	state, wait, _, _ = r.advanceSubscribePollState(state, pollErr)
+	assert.Equal(t, time.Second*4, wait)
+	// 4s * 2 = 8s, capped at subscribeMaxRetryDelay (8s).
+	assert.Equal(t, time.Second*8, state.retryDelay)
+
+	_, _, _, _ = r.advanceSubscribePollState(state, pollErr)
+	state, _, _, _ = r.advanceSubscribePollState(state, pollErr)
+	assert.LessOrEqual(t, state.retryDelay, r.metadata.subscribeMaxRetryDelay)
+}
+
+func TestAdvanceSubscribePollState_NotifiesAndResetsOnContinuePolicy(t *testing.T) {
+	r := newTestStore(subscribeOnErrorContinue)
+	state := subscribePollState{retryDelay: time.Second}
+	pollErr := errors.New("boom")
+
+	var notifyFailures int
+	var haltLoop bool
+	for i := 0; i < r.metadata.subscribeMaxConsecutiveFailures; i++ {
+		state, _, notifyFailures, haltLoop = r.advanceSubscribePollState(state, pollErr)
+	}
+
+	assert.Equal(t, r.metadata.subscribeMaxConsecutiveFailures, notifyFailures)
+	assert.False(t, haltLoop)
+	assert.Equal(t, 0, state.consecutiveFailures, "continue policy should reset the counter after notifying")
+}
+
+func TestAdvanceSubscribePollState_HaltsOnStopPolicy(t *testing.T) {
+	r := newTestStore(subscribeOnErrorStop)
+	state := subscribePollState{retryDelay: time.Second}
+	pollErr := errors.New("boom")
+
+	var notifyFailures int
+	var haltLoop bool
+	for i := 0; i < r.metadata.subscribeMaxConsecutiveFailures; i++ {
+		state, _, notifyFailures, haltLoop = r.advanceSubscribePollState(state, pollErr)
+	}
+
+	assert.Equal(t, r.metadata.subscribeMaxConsecutiveFailures, notifyFailures)
+	assert.True(t, haltLoop)
+}
+
+func TestNotifySubscribeError(t *testing.T) {
+	r := newTestStore(subscribeOnErrorContinue)
+	r.logger = logger.NewLogger("test")
+
+	var gotID string
+	var gotItems map[string]*configuration.Item
+	handler := func(_ context.Context, e *configuration.UpdateEvent) error {
+		gotID = e.ID
+		gotItems = e.Items
+		return nil
+	}
+
+	r.notifySubscribeError(context.Background(), handler, "sub-1", errors.New("poll failed"), 7)
+
+	assert.Equal(t, "sub-1", gotID)
+	item, ok := gotItems[subscribeErrorItemKey]
+	assert.True(t, ok)
+	assert.Equal(t, "poll failed", item.Value)
+	assert.Equal(t, "true", item.Metadata["error"])
+	assert.Equal(t, "7", item.Metadata["consecutiveFailures"])
+}
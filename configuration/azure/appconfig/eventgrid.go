@@ -0,0 +1,202 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azappconfig"
+
+	"github.com/dapr/components-contrib/configuration"
+)
+
+const (
+	eventGridValidationEventType = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	eventGridKeyValueModified    = "Microsoft.AppConfiguration.KeyValueModified"
+	eventGridKeyValueDeleted     = "Microsoft.AppConfiguration.KeyValueDeleted"
+
+	webhookRequestOriginHeader = "WebHook-Request-Origin"
+	webhookAllowedOriginHeader = "WebHook-Allowed-Origin"
+	webhookAllowedRateHeader   = "WebHook-Allowed-Rate"
+)
+
+// cloudEvent is the subset of the CloudEvents v1.0 envelope Event Grid delivers.
+type cloudEvent struct {
+	ID          string          `json:"id"`
+	Source      string          `json:"source"`
+	Type        string          `json:"type"`
+	Data        json.RawMessage `json:"data"`
+	DataVersion string          `json:"dataversion"`
+}
+
+type subscriptionValidationData struct {
+	ValidationCode string `json:"validationCode"`
+}
+
+type keyValueEventData struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// startPushListener starts the HTTP endpoint Event Grid delivers key/value change notifications
+// to, as an alternative (or, in hybrid mode, a complement) to doSubscribe's polling.
+func (r *ConfigurationStore) startPushListener() error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(r.metadata.pushPath, r.handleEventGridRequest)
+
+	r.pushServer = &http.Server{
+		Addr:    r.metadata.pushBindAddress,
+		Handler: mux,
+	}
+
+	go func() {
+		if err := r.pushServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			r.logger.Errorf("azure appconfig: push listener stopped: %s", err)
+		}
+	}()
+
+	return nil
+}
+
+func (r *ConfigurationStore) handleEventGridRequest(w http.ResponseWriter, req *http.Request) {
+	// CloudEvents abuse-protection handshake: echo the requested origin back so Event Grid knows
+	// this endpoint is willing to receive events from it.
+	if origin := req.Header.Get(webhookRequestOriginHeader); origin != "" {
+		w.Header().Set(webhookAllowedOriginHeader, origin)
+		w.Header().Set(webhookAllowedRateHeader, "120")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer req.Body.Close()
+
+	var events []cloudEvent
+	if err := json.Unmarshal(body, &events); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range events {
+		if event.Type == eventGridValidationEventType {
+			r.respondToSubscriptionValidation(w, event)
+			return
+		}
+		r.handleKeyValueEvent(req.Context(), event)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (r *ConfigurationStore) respondToSubscriptionValidation(w http.ResponseWriter, event cloudEvent) {
+	var data subscriptionValidationData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]string{"validationResponse": data.ValidationCode})
+}
+
+func (r *ConfigurationStore) handleKeyValueEvent(ctx context.Context, event cloudEvent) {
+	if event.Type != eventGridKeyValueModified && event.Type != eventGridKeyValueDeleted {
+		return
+	}
+
+	var data keyValueEventData
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		r.logger.Errorf("azure appconfig: failed to parse event grid event data: %s", err)
+		return
+	}
+
+	matchedSubIDs := r.subIDsForKey(data.Key, data.Label)
+
+	for _, subID := range matchedSubIDs {
+		handlerVal, ok := r.subscribeHandlerMap.Load(subID)
+		if !ok {
+			continue
+		}
+
+		item, err := r.fetchPushedItem(ctx, event.Type, data)
+		if err != nil {
+			r.logger.Errorf("azure appconfig: failed to fetch key %s after push notification: %s", data.Key, err)
+			continue
+		}
+
+		r.handleSubscribedChange(ctx, handlerVal.(configuration.UpdateHandler), map[string]*configuration.Item{data.Key: item}, subID)
+	}
+}
+
+// subIDsForKey returns the IDs of every active subscription whose key list includes key and whose
+// label matches the label the change actually occurred under, so a subscription scoped to one
+// label (e.g. "prod") is never handed a push update that happened under a different label (e.g.
+// "dev") on the same key name. It takes subMu so it can be called safely from the Event Grid
+// webhook goroutine while Subscribe/Unsubscribe are mutating the same maps from another goroutine.
+func (r *ConfigurationStore) subIDsForKey(key, label string) []string {
+	r.subMu.Lock()
+	defer r.subMu.Unlock()
+
+	matched := make([]string, 0)
+	for subID, keys := range r.subIDToKeyListMap {
+		if containsKey(keys, key) && r.subIDToLabelMap[subID] == label {
+			matched = append(matched, subID)
+		}
+	}
+
+	return matched
+}
+
+func (r *ConfigurationStore) fetchPushedItem(ctx context.Context, eventType string, data keyValueEventData) (*configuration.Item, error) {
+	if eventType == eventGridKeyValueDeleted {
+		item := &configuration.Item{Metadata: map[string]string{"deleted": "true"}}
+		if data.Label != "" {
+			item.Metadata["label"] = data.Label
+		}
+		return item, nil
+	}
+
+	var label *string
+	if data.Label != "" {
+		label = to.Ptr(data.Label)
+	}
+
+	resp, err := r.client.GetSetting(ctx, data.Key, &azappconfig.GetSettingOptions{Label: label})
+	if err != nil {
+		return nil, err
+	}
+
+	item := settingToItem(resp.Label, resp.ContentType, resp.Value)
+	r.resolveKeyVaultRef(ctx, item)
+
+	return item, nil
+}
+
+func containsKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
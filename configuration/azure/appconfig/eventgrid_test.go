@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestStoreForPush() *ConfigurationStore {
+	return &ConfigurationStore{
+		subIDToKeyListMap: map[string][]string{
+			"sub-prod": {"shared-key"},
+			"sub-dev":  {"shared-key"},
+			"sub-none": {"shared-key"},
+		},
+		subIDToLabelMap: map[string]string{
+			"sub-prod": "prod",
+			"sub-dev":  "dev",
+			"sub-none": "",
+		},
+	}
+}
+
+func TestSubIDsForKey_OnlyMatchesSameLabel(t *testing.T) {
+	r := newTestStoreForPush()
+
+	assert.ElementsMatch(t, []string{"sub-prod"}, r.subIDsForKey("shared-key", "prod"))
+	assert.ElementsMatch(t, []string{"sub-dev"}, r.subIDsForKey("shared-key", "dev"))
+	assert.ElementsMatch(t, []string{"sub-none"}, r.subIDsForKey("shared-key", ""))
+}
+
+func TestSubIDsForKey_NoMatchForUnknownKey(t *testing.T) {
+	r := newTestStoreForPush()
+
+	assert.Empty(t, r.subIDsForKey("other-key", "prod"))
+}
@@ -0,0 +1,328 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/data/azappconfig"
+
+	"github.com/dapr/components-contrib/configuration"
+)
+
+const (
+	// featureFlagContentType is the content type Azure App Configuration assigns to feature flag settings.
+	featureFlagContentType = "application/vnd.microsoft.appconfig.ff+json;charset=utf-8"
+	// featureFlagPrefix is prepended to the flag name to form the underlying setting key.
+	featureFlagPrefix = ".appconfig.featureflag/"
+
+	targetingFilterName  = "Microsoft.Targeting"
+	timeWindowFilterName = "Microsoft.TimeWindow"
+	percentageFilterName = "Microsoft.Percentage"
+)
+
+// FeatureFlag is the parsed representation of an Azure App Configuration feature flag setting.
+type FeatureFlag struct {
+	ID          string                `json:"id"`
+	Description string                `json:"description,omitempty"`
+	Enabled     bool                  `json:"enabled"`
+	Conditions  FeatureFlagConditions `json:"conditions"`
+}
+
+// FeatureFlagConditions holds the client filters that gate a feature flag.
+type FeatureFlagConditions struct {
+	ClientFilters []FeatureFlagClientFilter `json:"client_filters"`
+}
+
+// FeatureFlagClientFilter is a single named filter with its filter-specific parameters.
+type FeatureFlagClientFilter struct {
+	Name       string                 `json:"name"`
+	Parameters map[string]interface{} `json:"parameters"`
+}
+
+// FeatureFlagEvalContext carries the caller-supplied context used to resolve client filters
+// such as Targeting, TimeWindow and Percentage into an enabled/disabled decision.
+type FeatureFlagEvalContext struct {
+	User   string
+	Groups []string
+	Time   time.Time
+}
+
+// GetFeatureFlagsRequest is the input to ConfigurationStore.GetFeatureFlags.
+// Keys are flag IDs; the `.appconfig.featureflag/` prefix is added automatically if missing.
+// Leaving Keys empty fetches every feature flag setting in the store.
+type GetFeatureFlagsRequest struct {
+	Keys        []string
+	Metadata    map[string]string
+	EvalContext *FeatureFlagEvalContext
+}
+
+// GetFeatureFlagsResponse returns the parsed flags keyed by flag ID, and, when EvalContext was
+// provided, the resolved enabled/disabled Decisions for each flag.
+type GetFeatureFlagsResponse struct {
+	Flags     map[string]*FeatureFlag
+	Decisions map[string]bool
+}
+
+// GetFeatureFlags retrieves and parses Azure App Configuration feature flag settings so that
+// callers don't need to reparse the raw JSON value or reimplement filter evaluation themselves.
+func (r *ConfigurationStore) GetFeatureFlags(ctx context.Context, req *GetFeatureFlagsRequest) (*GetFeatureFlagsResponse, error) {
+	flags := make(map[string]*FeatureFlag)
+
+	if len(req.Keys) == 0 {
+		labelFilter := r.getLabelFromMetadata(req.Metadata)
+		if labelFilter == nil {
+			labelFilter = to.Ptr("*")
+		}
+
+		pager := r.client.NewListSettingsPager(
+			azappconfig.SettingSelector{
+				KeyFilter:   to.Ptr(featureFlagPrefix + "*"),
+				LabelFilter: labelFilter,
+				Fields:      azappconfig.AllSettingFields(),
+			},
+			nil)
+
+		for pager.More() {
+			page, err := pager.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load feature flags, error is %s", err)
+			}
+			for _, setting := range page.Settings {
+				if setting.ContentType == nil || *setting.ContentType != featureFlagContentType {
+					continue
+				}
+				flag, err := parseFeatureFlag(*setting.Value)
+				if err != nil {
+					return nil, fmt.Errorf("failed to parse feature flag %s: %w", *setting.Key, err)
+				}
+				flags[flagID(*setting.Key)] = flag
+			}
+		}
+	} else {
+		for _, key := range req.Keys {
+			settingKey := key
+			if !strings.HasPrefix(settingKey, featureFlagPrefix) {
+				settingKey = featureFlagPrefix + settingKey
+			}
+
+			resp, err := r.client.GetSetting(
+				ctx,
+				settingKey,
+				&azappconfig.GetSettingOptions{
+					Label: r.getLabelFromMetadata(req.Metadata),
+				},
+			)
+			if err != nil {
+				return nil, err
+			}
+			if resp.ContentType == nil || *resp.ContentType != featureFlagContentType {
+				return nil, fmt.Errorf("azure appconfig error: setting %s is not a feature flag", settingKey)
+			}
+
+			flag, err := parseFeatureFlag(*resp.Value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse feature flag %s: %w", settingKey, err)
+			}
+			flags[flagID(settingKey)] = flag
+		}
+	}
+
+	resp := &GetFeatureFlagsResponse{Flags: flags}
+	if req.EvalContext != nil {
+		decisions := make(map[string]bool, len(flags))
+		for id, flag := range flags {
+			decisions[id] = evaluateFeatureFlag(flag, req.EvalContext)
+		}
+		resp.Decisions = decisions
+	}
+
+	return resp, nil
+}
+
+// FeatureFlagUpdateEvent is the typed event SubscribeFeatureFlags delivers to its handler.
+type FeatureFlagUpdateEvent struct {
+	ID    string
+	Flags map[string]*FeatureFlag
+}
+
+// FeatureFlagUpdateHandler receives parsed flags whenever a subscribed feature flag changes.
+type FeatureFlagUpdateHandler func(ctx context.Context, e *FeatureFlagUpdateEvent) error
+
+// SubscribeFeatureFlags subscribes to feature flag settings through the same poll/push/hybrid
+// machinery as Subscribe, but parses each changed setting into a FeatureFlag before invoking
+// handler, so callers get typed flag-change events instead of having to tag and reparse the raw
+// JSON value themselves.
+func (r *ConfigurationStore) SubscribeFeatureFlags(ctx context.Context, req *GetFeatureFlagsRequest, handler FeatureFlagUpdateHandler) (string, error) {
+	keys := make([]string, len(req.Keys))
+	for i, key := range req.Keys {
+		if !strings.HasPrefix(key, featureFlagPrefix) {
+			key = featureFlagPrefix + key
+		}
+		keys[i] = key
+	}
+
+	subReq := &configuration.SubscribeRequest{
+		Keys:     keys,
+		Metadata: req.Metadata,
+	}
+
+	return r.Subscribe(ctx, subReq, featureFlagUpdateAdapter(handler))
+}
+
+// featureFlagUpdateAdapter turns a FeatureFlagUpdateHandler into a configuration.UpdateHandler by
+// parsing every isFeatureFlag-tagged item in the event before forwarding it, so the typed path can
+// reuse doSubscribe/handleKeyValueEvent/handleSubscribedChange unchanged.
+func featureFlagUpdateAdapter(handler FeatureFlagUpdateHandler) configuration.UpdateHandler {
+	return func(ctx context.Context, e *configuration.UpdateEvent) error {
+		if _, isPollError := e.Items[subscribeErrorItemKey]; isPollError {
+			return nil
+		}
+
+		flags := make(map[string]*FeatureFlag, len(e.Items))
+		for key, item := range e.Items {
+			if item.Metadata["isFeatureFlag"] != "true" {
+				continue
+			}
+			flag, err := parseFeatureFlag(item.Value)
+			if err != nil {
+				return fmt.Errorf("failed to parse feature flag %s: %w", key, err)
+			}
+			flags[flagID(key)] = flag
+		}
+
+		if len(flags) == 0 {
+			return nil
+		}
+
+		return handler(ctx, &FeatureFlagUpdateEvent{ID: e.ID, Flags: flags})
+	}
+}
+
+func flagID(settingKey string) string {
+	return strings.TrimPrefix(settingKey, featureFlagPrefix)
+}
+
+func parseFeatureFlag(value string) (*FeatureFlag, error) {
+	flag := &FeatureFlag{}
+	if err := json.Unmarshal([]byte(value), flag); err != nil {
+		return nil, err
+	}
+	return flag, nil
+}
+
+// evaluateFeatureFlag resolves a feature flag against an evaluation context following the
+// Targeting/TimeWindow/Percentage semantics used by Microsoft.FeatureManagement. Client filters
+// are combined with "Any" semantics: the flag is enabled if it is on and at least one filter matches.
+func evaluateFeatureFlag(flag *FeatureFlag, evalCtx *FeatureFlagEvalContext) bool {
+	if !flag.Enabled {
+		return false
+	}
+	if len(flag.Conditions.ClientFilters) == 0 {
+		return true
+	}
+
+	for _, filter := range flag.Conditions.ClientFilters {
+		switch filter.Name {
+		case targetingFilterName:
+			if evaluateTargetingFilter(filter.Parameters, evalCtx) {
+				return true
+			}
+		case timeWindowFilterName:
+			if evaluateTimeWindowFilter(filter.Parameters, evalCtx) {
+				return true
+			}
+		case percentageFilterName:
+			if evaluatePercentageFilter(filter.Parameters, flag.ID, evalCtx) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func evaluateTargetingFilter(params map[string]interface{}, evalCtx *FeatureFlagEvalContext) bool {
+	audience, ok := params["Audience"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	if users, ok := audience["Users"].([]interface{}); ok {
+		for _, u := range users {
+			if s, ok := u.(string); ok && s == evalCtx.User {
+				return true
+			}
+		}
+	}
+
+	if groups, ok := audience["Groups"].([]interface{}); ok {
+		for _, g := range groups {
+			group, ok := g.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := group["Name"].(string)
+			for _, userGroup := range evalCtx.Groups {
+				if userGroup == name {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+func evaluateTimeWindowFilter(params map[string]interface{}, evalCtx *FeatureFlagEvalContext) bool {
+	t := evalCtx.Time
+	if start, ok := params["Start"].(string); ok && start != "" {
+		startTime, err := time.Parse(time.RFC1123, start)
+		if err != nil {
+			return false
+		}
+		if t.Before(startTime) {
+			return false
+		}
+	}
+	if end, ok := params["End"].(string); ok && end != "" {
+		endTime, err := time.Parse(time.RFC1123, end)
+		if err != nil {
+			return false
+		}
+		if t.After(endTime) {
+			return false
+		}
+	}
+	return true
+}
+
+func evaluatePercentageFilter(params map[string]interface{}, flagID string, evalCtx *FeatureFlagEvalContext) bool {
+	value, ok := params["Value"].(float64)
+	if !ok {
+		return false
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(flagID + ":" + evalCtx.User))
+	bucket := float64(h.Sum32() % 100)
+
+	return bucket < value
+}
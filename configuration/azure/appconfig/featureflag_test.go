@@ -0,0 +1,128 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/dapr/components-contrib/configuration"
+)
+
+func TestEvaluateFeatureFlag_DisabledAlwaysFalse(t *testing.T) {
+	flag := &FeatureFlag{ID: "f1", Enabled: false}
+	assert.False(t, evaluateFeatureFlag(flag, &FeatureFlagEvalContext{}))
+}
+
+func TestEvaluateFeatureFlag_NoFiltersMeansEnabled(t *testing.T) {
+	flag := &FeatureFlag{ID: "f1", Enabled: true}
+	assert.True(t, evaluateFeatureFlag(flag, &FeatureFlagEvalContext{}))
+}
+
+func TestEvaluateTargetingFilter(t *testing.T) {
+	params := map[string]interface{}{
+		"Audience": map[string]interface{}{
+			"Users": []interface{}{"alice", "bob"},
+			"Groups": []interface{}{
+				map[string]interface{}{"Name": "beta-testers"},
+			},
+		},
+	}
+
+	assert.True(t, evaluateTargetingFilter(params, &FeatureFlagEvalContext{User: "alice"}))
+	assert.True(t, evaluateTargetingFilter(params, &FeatureFlagEvalContext{User: "carol", Groups: []string{"beta-testers"}}))
+	assert.False(t, evaluateTargetingFilter(params, &FeatureFlagEvalContext{User: "carol", Groups: []string{"other"}}))
+}
+
+func TestEvaluateTimeWindowFilter(t *testing.T) {
+	start := "Mon, 01 Jan 2024 00:00:00 GMT"
+	end := "Wed, 31 Jan 2024 00:00:00 GMT"
+	params := map[string]interface{}{"Start": start, "End": end}
+
+	inWindow := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	beforeWindow := time.Date(2023, 12, 1, 0, 0, 0, 0, time.UTC)
+	afterWindow := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	assert.True(t, evaluateTimeWindowFilter(params, &FeatureFlagEvalContext{Time: inWindow}))
+	assert.False(t, evaluateTimeWindowFilter(params, &FeatureFlagEvalContext{Time: beforeWindow}))
+	assert.False(t, evaluateTimeWindowFilter(params, &FeatureFlagEvalContext{Time: afterWindow}))
+}
+
+func TestEvaluatePercentageFilter_ZeroAndHundred(t *testing.T) {
+	evalCtx := &FeatureFlagEvalContext{User: "alice"}
+
+	assert.False(t, evaluatePercentageFilter(map[string]interface{}{"Value": float64(0)}, "f1", evalCtx))
+	assert.True(t, evaluatePercentageFilter(map[string]interface{}{"Value": float64(100)}, "f1", evalCtx))
+}
+
+func TestEvaluatePercentageFilter_MissingValue(t *testing.T) {
+	assert.False(t, evaluatePercentageFilter(map[string]interface{}{}, "f1", &FeatureFlagEvalContext{User: "alice"}))
+}
+
+func TestFeatureFlagUpdateAdapter_ParsesTaggedItemsOnly(t *testing.T) {
+	var got *FeatureFlagUpdateEvent
+	handler := func(_ context.Context, e *FeatureFlagUpdateEvent) error {
+		got = e
+		return nil
+	}
+
+	adapter := featureFlagUpdateAdapter(handler)
+
+	event := &configuration.UpdateEvent{
+		ID: "sub-1",
+		Items: map[string]*configuration.Item{
+			featureFlagPrefix + "beta": {
+				Value:    `{"id":"beta","enabled":true,"conditions":{"client_filters":[]}}`,
+				Metadata: map[string]string{"isFeatureFlag": "true"},
+			},
+			"plain-key": {
+				Value:    "untouched",
+				Metadata: map[string]string{},
+			},
+		},
+	}
+
+	err := adapter(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.NotNil(t, got)
+	assert.Equal(t, "sub-1", got.ID)
+	assert.Len(t, got.Flags, 1)
+	assert.True(t, got.Flags["beta"].Enabled)
+}
+
+func TestFeatureFlagUpdateAdapter_SkipsPollErrorEvents(t *testing.T) {
+	called := false
+	handler := func(_ context.Context, e *FeatureFlagUpdateEvent) error {
+		called = true
+		return nil
+	}
+
+	adapter := featureFlagUpdateAdapter(handler)
+
+	event := &configuration.UpdateEvent{
+		ID: "sub-1",
+		Items: map[string]*configuration.Item{
+			subscribeErrorItemKey: {Value: "boom", Metadata: map[string]string{"error": "true"}},
+		},
+	}
+
+	err := adapter(context.Background(), event)
+
+	assert.NoError(t, err)
+	assert.False(t, called)
+}
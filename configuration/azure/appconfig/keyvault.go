@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/keyvault/azsecrets"
+
+	"github.com/dapr/components-contrib/configuration"
+)
+
+// keyVaultRefContentType is the content type Azure App Configuration assigns to settings whose
+// value is a reference to an Azure Key Vault secret.
+const keyVaultRefContentType = "application/vnd.microsoft.appconfig.keyvaultref+json;charset=utf-8"
+
+const keyVaultCacheCapacity = 256
+
+type keyVaultRef struct {
+	URI string `json:"uri"`
+}
+
+// resolveKeyVaultRef replaces item.Value with the secret it points to when the setting is a Key
+// Vault reference. Resolution failures are logged and left as a non-fatal warning: the caller
+// still gets the unresolved reference back under Item.Metadata["keyVaultRef"].
+func (r *ConfigurationStore) resolveKeyVaultRef(ctx context.Context, item *configuration.Item) {
+	if !r.metadata.resolveKeyVaultRefs || item.Metadata["contentType"] != keyVaultRefContentType {
+		return
+	}
+
+	var ref keyVaultRef
+	if err := json.Unmarshal([]byte(item.Value), &ref); err != nil {
+		r.logger.Warnf("azure appconfig: failed to parse key vault reference: %s", err)
+		return
+	}
+	item.Metadata["keyVaultRef"] = ref.URI
+
+	secret, err := r.getKeyVaultSecret(ctx, ref.URI)
+	if err != nil {
+		r.logger.Warnf("azure appconfig: failed to resolve key vault reference %s: %s", ref.URI, err)
+		return
+	}
+	item.Value = secret
+}
+
+func (r *ConfigurationStore) getKeyVaultSecret(ctx context.Context, uri string) (string, error) {
+	vaultURL, name, version, err := parseKeyVaultRefURI(uri)
+	if err != nil {
+		return "", err
+	}
+
+	cacheKey := vaultURL + "/" + name + "/" + version
+	if value, ok := r.keyVaultCache.get(cacheKey); ok {
+		return value, nil
+	}
+
+	client, err := r.getSecretsClient(vaultURL)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := client.GetSecret(ctx, name, version, nil)
+	if err != nil {
+		return "", err
+	}
+
+	value := *resp.Value
+	r.keyVaultCache.set(cacheKey, value, r.metadata.keyVaultRefCacheTTL)
+
+	return value, nil
+}
+
+func (r *ConfigurationStore) getSecretsClient(vaultURL string) (*azsecrets.Client, error) {
+	if v, ok := r.secretClients.Load(vaultURL); ok {
+		return v.(*azsecrets.Client), nil
+	}
+
+	if r.cred == nil {
+		return nil, fmt.Errorf("azure appconfig error: no token credential available to resolve key vault references")
+	}
+
+	client, err := azsecrets.NewClient(vaultURL, r.cred, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	r.secretClients.Store(vaultURL, client)
+
+	return client, nil
+}
+
+// parseKeyVaultRefURI splits a Key Vault reference URI of the form
+// https://<vault>.vault.azure.net/secrets/<name>/<version> into its vault URL, secret name and
+// (optional) version.
+func parseKeyVaultRefURI(uri string) (vaultURL, name, version string, err error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) < 2 || parts[0] != "secrets" {
+		return "", "", "", fmt.Errorf("invalid key vault reference uri: %s", uri)
+	}
+
+	name = parts[1]
+	if len(parts) > 2 {
+		version = parts[2]
+	}
+	vaultURL = u.Scheme + "://" + u.Host
+
+	return vaultURL, name, version, nil
+}
+
+// ttlLRUCache is a small fixed-capacity, TTL-expiring cache used to avoid storming Key Vault when
+// a GetAll resolves many references at once.
+type ttlLRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type ttlCacheEntry struct {
+	key       string
+	value     string
+	expiresAt time.Time
+}
+
+func newTTLLRUCache(capacity int) *ttlLRUCache {
+	return &ttlLRUCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (c *ttlLRUCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return "", false
+	}
+
+	entry := el.Value.(*ttlCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.ll.Remove(el)
+		delete(c.items, key)
+		return "", false
+	}
+
+	c.ll.MoveToFront(el)
+
+	return entry.value, true
+}
+
+func (c *ttlLRUCache) set(key, value string, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.ll.MoveToFront(el)
+		entry := el.Value.(*ttlCacheEntry)
+		entry.value = value
+		entry.expiresAt = time.Now().Add(ttl)
+		return
+	}
+
+	el := c.ll.PushFront(&ttlCacheEntry{key: key, value: value, expiresAt: time.Now().Add(ttl)})
+	c.items[key] = el
+
+	if c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*ttlCacheEntry).key)
+		}
+	}
+}
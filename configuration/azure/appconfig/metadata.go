@@ -0,0 +1,41 @@
+/*
+Copyright 2021 The Dapr Authors
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+    http://www.apache.org/licenses/LICENSE-2.0
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package appconfig
+
+import "time"
+
+type metadata struct {
+	host                  string
+	connectionString      string
+	maxRetries            int
+	maxRetryDelay         time.Duration
+	retryDelay            time.Duration
+	subscribePollInterval time.Duration
+
+	// subscribeMode is one of poll, push or hybrid. See subscribeMode* constants.
+	subscribeMode   string
+	pushBindAddress string
+	pushPath        string
+
+	resolveKeyVaultRefs bool
+	keyVaultRefCacheTTL time.Duration
+
+	// subscribeMaxConsecutiveFailures and the retry delays drive exponential backoff between
+	// failed polls in doSubscribe; subscribeOnErrorPolicy (continue|stop) decides what happens
+	// once subscribeMaxConsecutiveFailures is reached.
+	subscribeMaxConsecutiveFailures int
+	subscribeRetryDelay             time.Duration
+	subscribeMaxRetryDelay          time.Duration
+	subscribeOnErrorPolicy          string
+}